@@ -0,0 +1,154 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errInitializing is the cached result an AsyncCheck reports before its
+// background goroutine has completed a first run.
+var errInitializing = errors.New("initializing")
+
+// defaultStaleAfterTicks is how many missed intervals an AsyncCheck
+// tolerates before it considers its cached result stale.
+const defaultStaleAfterTicks = 5
+
+// AsyncOption configures an AsyncCheck constructed by AsyncWithContext.
+type AsyncOption func(*asyncConfig)
+
+type asyncConfig struct {
+	staleAfter time.Duration
+}
+
+// WithStaleAfter overrides the default staleness threshold: if the
+// background goroutine hasn't produced a new result within this long
+// (e.g. because the check is wedged), Check reports a "stale result"
+// error instead of returning the last cached value.
+func WithStaleAfter(d time.Duration) AsyncOption {
+	return func(c *asyncConfig) {
+		c.staleAfter = d
+	}
+}
+
+// asyncResult is an AsyncCheck's cached result.
+type asyncResult struct {
+	err       error
+	updatedAt time.Time
+}
+
+// AsyncCheck runs a CheckContext on a ticker in the background and caches
+// its latest result, so that a slow or hanging dependency doesn't block
+// whatever is calling Check. Construct one with AsyncWithContext.
+type AsyncCheck struct {
+	resultMu sync.Mutex
+	result   asyncResult
+
+	cancel     context.CancelFunc
+	staleAfter time.Duration
+}
+
+// AsyncWithContext starts a background goroutine that runs check every
+// interval, caching its latest result. The returned AsyncCheck's Check
+// method can be registered like any other CheckContext; its Stop method
+// cancels the background goroutine. The goroutine also exits if ctx is
+// done first.
+func AsyncWithContext(ctx context.Context, check CheckContext, interval time.Duration, opts ...AsyncOption) *AsyncCheck {
+	cfg := asyncConfig{staleAfter: interval * defaultStaleAfterTicks}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	a := &AsyncCheck{
+		cancel:     cancel,
+		staleAfter: cfg.staleAfter,
+	}
+	a.store(asyncResult{err: errInitializing})
+
+	go a.run(runCtx, check, interval)
+	return a
+}
+
+func (a *AsyncCheck) run(ctx context.Context, check CheckContext, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		a.store(asyncResult{err: check(ctx), updatedAt: time.Now()})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (a *AsyncCheck) store(res asyncResult) {
+	a.resultMu.Lock()
+	defer a.resultMu.Unlock()
+	a.result = res
+}
+
+// Check returns the cached result of the background check. Before the
+// first run completes it returns an "initializing" error; if the
+// background goroutine has gone longer than the staleness threshold
+// without producing a new result, it returns a "stale result" error
+// instead of the (possibly very old) cached value.
+func (a *AsyncCheck) Check(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	a.resultMu.Lock()
+	res := a.result
+	a.resultMu.Unlock()
+
+	if res.err == errInitializing {
+		return res.err
+	}
+	if age := time.Since(res.updatedAt); age > a.staleAfter {
+		return fmt.Errorf("stale result: last updated %s ago", age.Round(time.Second))
+	}
+	return res.err
+}
+
+// Stop cancels the background goroutine. Check continues to return its
+// last cached result (subject to staleness) afterward.
+func (a *AsyncCheck) Stop() {
+	a.cancel()
+}
+
+// Async converts a Check that may take a long time or hang into one that
+// returns immediately by running it on a timer in the background and
+// caching the latest result. This is useful for expensive checks (e.g. an
+// HTTP request to an upstream dependency) that shouldn't block the health
+// endpoint.
+//
+// Deprecated: use AsyncWithContext, which ties the background goroutine's
+// lifetime to a context (and lets it be stopped explicitly) instead of
+// running it for the life of the process.
+func Async(check Check, interval time.Duration) Check {
+	a := AsyncWithContext(context.Background(), adaptCheck(check), interval)
+	return func() error {
+		return a.Check(context.Background())
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// GoroutineCountCheck returns a Check that fails if too many goroutines are
+// running (which could indicate a leak). On failure, the current
+// goroutine count is attached as a *CheckError's ObservedValue; register
+// it with CheckInfo{ObservedUnit: "goroutines"} to surface that in the
+// detailed ("?full=1") health report.
+func GoroutineCountCheck(threshold int) Check {
+	return func() error {
+		count := runtime.NumGoroutine()
+		if count > threshold {
+			return &CheckError{
+				Err:           fmt.Errorf("too many goroutines (%d > %d)", count, threshold),
+				ObservedValue: count,
+			}
+		}
+		return nil
+	}
+}
+
+// GCMaxPauseCheck returns a Check that fails if the most recent garbage
+// collection pause exceeded threshold, which can be a useful early warning
+// that a service is about to miss its latency budget. On failure, the
+// pause, in nanoseconds, is attached as a *CheckError's ObservedValue;
+// register it with CheckInfo{ObservedUnit: "ns"} to surface that in the
+// detailed ("?full=1") health report.
+func GCMaxPauseCheck(threshold time.Duration) Check {
+	return func() error {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		pause := time.Duration(stats.PauseNs[(stats.NumGC+255)%256])
+		if pause > threshold {
+			return &CheckError{
+				Err:           fmt.Errorf("last GC pause of %s exceeded threshold of %s", pause, threshold),
+				ObservedValue: pause.Nanoseconds(),
+			}
+		}
+		return nil
+	}
+}
+
+// DNSResolveCheck returns a Check that makes sure the provided host can
+// resolve within the timeout.
+//
+// Deprecated: use DNSResolveCheckContext.
+func DNSResolveCheck(host string, timeout time.Duration) Check {
+	return legacy(DNSResolveCheckContext(host), timeout)
+}
+
+// DNSResolveCheckContext returns a CheckContext that makes sure the
+// provided host can resolve before ctx is done.
+func DNSResolveCheckContext(host string) CheckContext {
+	var resolver net.Resolver
+	return func(ctx context.Context) error {
+		addrs, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return err
+		}
+		if len(addrs) < 1 {
+			return fmt.Errorf("could not resolve host %q", host)
+		}
+		return nil
+	}
+}
+
+// TCPDialCheck returns a Check that dials the given address over TCP.
+//
+// Deprecated: use TCPDialCheckContext.
+func TCPDialCheck(addr string, timeout time.Duration) Check {
+	return legacy(TCPDialCheckContext(addr), timeout)
+}
+
+// TCPDialCheckContext returns a CheckContext that dials the given address
+// over TCP, aborting if ctx is done first.
+func TCPDialCheckContext(addr string) CheckContext {
+	var dialer net.Dialer
+	return func(ctx context.Context) error {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// HTTPGetCheck returns a Check that performs an HTTP GET request against the
+// given URL. The check fails if the request doesn't return a 200 within the
+// timeout. It runs synchronously, so a slow dependency blocks the health
+// endpoint for up to timeout.
+//
+// Deprecated: use HTTPGetCheckContext.
+func HTTPGetCheck(url string, timeout time.Duration) Check {
+	return legacy(HTTPGetCheckContext(url), timeout)
+}
+
+// HTTPGetCheckContext returns a CheckContext that performs an HTTP GET
+// request against the given URL, carrying ctx so the request is aborted if
+// ctx is done first.
+func HTTPGetCheckContext(url string) CheckContext {
+	client := http.Client{
+		// never follow redirects
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// DatabasePingCheck returns a Check that validates connectivity to a
+// database/sql.DB using Ping().
+//
+// Deprecated: use DatabasePingCheckContext.
+func DatabasePingCheck(database *sql.DB, timeout time.Duration) Check {
+	return legacy(DatabasePingCheckContext(database), timeout)
+}
+
+// DatabasePingCheckContext returns a CheckContext that validates
+// connectivity to a database/sql.DB using PingContext.
+func DatabasePingCheckContext(database *sql.DB) CheckContext {
+	return func(ctx context.Context) error {
+		if database == nil {
+			return fmt.Errorf("database is nil")
+		}
+		return database.PingContext(ctx)
+	}
+}
+
+// legacy adapts a CheckContext into a Check by deriving a context bounded
+// by timeout, so the deprecated Check-returning built-ins can be built on
+// their context-aware variants.
+func legacy(check CheckContext, timeout time.Duration) Check {
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return check(ctx)
+	}
+}
+
+// Timeout adds a timeout to a Check. Note that the check does not
+// necessarily stop running if it times out; it just returns an error in
+// time.
+//
+// Deprecated: use TimeoutContext, which cancels the context passed to
+// check when the timeout elapses instead of leaving the goroutine running.
+func Timeout(check Check, timeout time.Duration) Check {
+	checkContext := TimeoutContext(adaptCheck(check), timeout)
+	return func() error {
+		return checkContext(context.Background())
+	}
+}
+
+// TimeoutContext adds a timeout to a CheckContext. Unlike Timeout, the
+// context passed to check is canceled as soon as the timeout elapses, so a
+// context-aware check can stop its in-flight work instead of leaking a
+// goroutine.
+func TimeoutContext(check CheckContext, timeout time.Duration) CheckContext {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		result := make(chan error, 1)
+		go func() {
+			result <- check(ctx)
+		}()
+
+		select {
+		case err := <-result:
+			return err
+		case <-ctx.Done():
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+	}
+}
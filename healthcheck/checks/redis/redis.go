@@ -0,0 +1,38 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis provides a healthcheck.CheckContext against a Redis
+// server. It lives in its own module (see this directory's go.mod) so that
+// consumers of the core healthcheck package don't have to pull in a Redis
+// client unless they actually use it.
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/squat/signal/healthcheck"
+)
+
+// PingCheck returns a healthcheck.CheckContext that pings a Redis server
+// through client, failing if the ping doesn't complete within timeout.
+func PingCheck(client redis.Cmdable, timeout time.Duration) healthcheck.CheckContext {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return client.Ping(ctx).Err()
+	}
+}
@@ -15,7 +15,9 @@
 package healthcheck
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -95,6 +97,30 @@ func Example_database() {
 	// }
 }
 
+func Example_context() {
+	// Create a Handler that we can use to register liveness and readiness checks.
+	healthchecks := NewHandler()
+
+	// Add a context-aware readiness check so that a client disconnecting
+	// (or the server shutting down) aborts the in-flight DNS lookup instead
+	// of leaking it.
+	healthchecks.AddReadinessCheckContext(
+		"upstream-dependency-dns",
+		DNSResolveCheckContext("localhost"))
+
+	// Make a request to the readiness endpoint and print the response.
+	fmt.Print(dumpRequest(healthchecks, "GET", "/ready"))
+
+	// Output:
+	// HTTP/1.1 200 OK
+	// Connection: close
+	// Content-Type: application/json; charset=utf-8
+	//
+	// {
+	//     "upstream-dependency-dns": "OK"
+	// }
+}
+
 func Example_advanced() {
 	upstream, _ := upstream() // Mock some upstream Server
 
@@ -141,13 +167,86 @@ func Example_advanced() {
 	// }
 }
 
+// Example_detailedReport shows how to register component metadata and read
+// the detailed, IETF health-check-response-draft-shaped report.
+func Example_detailedReport() {
+	healthchecks := NewHandler()
+	healthchecks.SetVersionInfo("1.0.0", "abc123")
+
+	healthchecks.AddReadinessCheckInfo(
+		"database",
+		func(ctx context.Context) error { return nil },
+		CheckInfo{ComponentType: ComponentDatastore},
+	)
+
+	// GoroutineCountCheck attaches the observed goroutine count to its
+	// *CheckError on failure; CheckInfo's ObservedUnit labels it in the
+	// report.
+	healthchecks.AddLivenessCheckInfo(
+		"goroutine-threshold",
+		adaptCheck(GoroutineCountCheck(0)),
+		CheckInfo{ComponentType: ComponentSystem, ObservedUnit: "goroutines"},
+	)
+
+	// A CheckError with a nil Err and Warn set to true reports a passing
+	// check that still wants to surface an observed value, rendered as
+	// "warn" rather than "pass" or "fail".
+	healthchecks.AddReadinessCheckInfo(
+		"cache-capacity",
+		adaptCheck(func() error {
+			return &CheckError{ObservedValue: 90, Warn: true}
+		}),
+		CheckInfo{ComponentType: ComponentDatastore, ObservedUnit: "percent"},
+	)
+
+	req, _ := http.NewRequest("GET", "/ready?full=1", nil)
+	rr := httptest.NewRecorder()
+	healthchecks.ReadyEndpoint(rr, req)
+
+	var report Report
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	database := report.Checks["database"][0]
+	fmt.Println(report.Version, database.Status, database.ComponentType)
+
+	goroutines := report.Checks["goroutine-threshold"][0]
+	fmt.Println(goroutines.Status, goroutines.ObservedUnit, goroutines.ObservedValue.(float64) > 0)
+
+	cache := report.Checks["cache-capacity"][0]
+	fmt.Println(cache.Status, cache.ObservedUnit, cache.ObservedValue)
+
+	// Output:
+	// 1.0.0 pass datastore
+	// fail goroutines true
+	// warn percent 90
+}
+
+func Example_async() {
+	calls := 0
+	async := AsyncWithContext(context.Background(), func(ctx context.Context) error {
+		calls++
+		return nil
+	}, 10*time.Millisecond)
+	defer async.Stop()
+
+	// Before the first run completes, the cached result reports that the
+	// check is still initializing.
+	fmt.Println(async.Check(context.Background()))
+
+	time.Sleep(100 * time.Millisecond)
+	fmt.Println(async.Check(context.Background()))
+
+	// Output:
+	// initializing
+	// <nil>
+}
+
 func Example_metrics() {
 	// Create a new Prometheus registry (you'd likely already have one of these).
 	registry := prometheus.NewRegistry()
 
 	// Create a metrics-exposing Handler for the Prometheus registry
 	// It wraps the default handler to add metrics.
-	healthchecks := NewMetricsHandler(NewHandler(), registry)
+	healthchecks := NewMetricsHandler(NewHandler(), NewPrometheusSink(registry))
 
 	// Add a simple readiness check that always fails.
 	healthchecks.AddReadinessCheck(
@@ -176,14 +275,18 @@ func Example_metrics() {
 	internal.HandleFunc("/live", healthchecks.LiveEndpoint)
 	internal.HandleFunc("/ready", healthchecks.ReadyEndpoint)
 
-	// Make a request to the metrics endpoint and print the response.
-	fmt.Println(dumpRequest(internal, "GET", "/metrics"))
+	// Make a request to the metrics endpoint. The PrometheusSink also
+	// records a healthcheck_duration_seconds histogram, but its values
+	// aren't deterministic, so only print the healthcheck gauge family.
+	for _, line := range strings.Split(dumpRequest(internal, "GET", "/metrics"), "\n") {
+		if strings.HasPrefix(line, "# HELP healthcheck ") ||
+			strings.HasPrefix(line, "# TYPE healthcheck ") ||
+			strings.HasPrefix(line, "healthcheck{") {
+			fmt.Println(line)
+		}
+	}
 
 	// Output:
-	// HTTP/1.1 200 OK
-	// Connection: close
-	// Content-Type: text/plain; version=0.0.4; charset=utf-8
-	//
 	// # HELP healthcheck Indicates if check is healthy (1 is healthy, 0 is unhealthy)
 	// # TYPE healthcheck gauge
 	// healthcheck{check="live",name="successful-check"} 1
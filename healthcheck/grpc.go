@@ -0,0 +1,179 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// watchPollInterval is how often Watch re-evaluates a service's checks to
+// detect a status transition.
+const watchPollInterval = 1 * time.Second
+
+// GRPCHandler implements the standard gRPC Health Checking Protocol
+// (grpc.health.v1.Health) on top of a Handler, so the same liveness and
+// readiness checks used for Kubernetes HTTP probes can also drive
+// gRPC-native health clients like Envoy or linkerd.
+//
+// The overall service (empty service name "") reflects the Handler's
+// combined liveness and readiness checks. Every check registered with the
+// Handler at construction time is also exposed under its own name, and
+// RegisterService can be used to add further services by hand.
+//
+// GRPCHandler embeds UnimplementedHealthServer for forward compatibility
+// with future grpc.health.v1.Health RPCs.
+type GRPCHandler struct {
+	grpc_health_v1.UnimplementedHealthServer
+
+	handler Handler
+
+	servicesMu sync.RWMutex
+	services   map[string]CheckContext
+}
+
+// NewGRPCHandler returns a GRPCHandler backed by the given Handler. The
+// overall readiness of handler is exposed under the empty service name "",
+// and every check already registered with handler is exposed under its own
+// name.
+func NewGRPCHandler(handler Handler) *GRPCHandler {
+	g := &GRPCHandler{
+		handler:  handler,
+		services: map[string]CheckContext{},
+	}
+	g.services[""] = g.overallStatus
+	for name, check := range handler.LivenessChecks() {
+		g.services[name] = check
+	}
+	for name, check := range handler.ReadinessChecks() {
+		g.services[name] = check
+	}
+	return g
+}
+
+// RegisterService exposes an individual context-aware check as its own
+// gRPC health checking service name.
+func (g *GRPCHandler) RegisterService(name string, check CheckContext) {
+	g.servicesMu.Lock()
+	defer g.servicesMu.Unlock()
+	g.services[name] = check
+}
+
+// lookup returns the check registered for name, if any.
+func (g *GRPCHandler) lookup(name string) (CheckContext, bool) {
+	g.servicesMu.RLock()
+	defer g.servicesMu.RUnlock()
+	check, ok := g.services[name]
+	return check, ok
+}
+
+// snapshot returns a copy of every currently registered service name and
+// check.
+func (g *GRPCHandler) snapshot() map[string]CheckContext {
+	g.servicesMu.RLock()
+	defer g.servicesMu.RUnlock()
+	out := make(map[string]CheckContext, len(g.services))
+	for name, check := range g.services {
+		out[name] = check
+	}
+	return out
+}
+
+// overallStatus reports readiness by running the wrapped Handler's own
+// combined liveness and readiness checks directly, carrying ctx through to
+// them.
+func (g *GRPCHandler) overallStatus(ctx context.Context) error {
+	for _, check := range g.handler.LivenessChecks() {
+		if err := check(ctx); err != nil {
+			return err
+		}
+	}
+	for _, check := range g.handler.ReadinessChecks() {
+		if err := check(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check implements the grpc.health.v1.Health Check RPC.
+func (g *GRPCHandler) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	check, ok := g.lookup(req.GetService())
+	if !ok {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN}, nil
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: statusFor(check(ctx))}, nil
+}
+
+// List implements the grpc.health.v1.Health List RPC, reporting the status
+// of every registered service in one call.
+func (g *GRPCHandler) List(ctx context.Context, req *grpc_health_v1.HealthListRequest) (*grpc_health_v1.HealthListResponse, error) {
+	statuses := make(map[string]*grpc_health_v1.HealthCheckResponse)
+	for name, check := range g.snapshot() {
+		statuses[name] = &grpc_health_v1.HealthCheckResponse{Status: statusFor(check(ctx))}
+	}
+	return &grpc_health_v1.HealthListResponse{Statuses: statuses}, nil
+}
+
+// Watch implements the grpc.health.v1.Health Watch RPC. It re-evaluates the
+// requested service's check on a timer and streams a message only when the
+// status transitions, as required by the protocol. If the service isn't
+// (yet) registered, it streams SERVICE_UNKNOWN instead of failing the
+// stream outright, so a watcher started before a later RegisterService
+// call still observes the eventual transition.
+func (g *GRPCHandler) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	var last grpc_health_v1.HealthCheckResponse_ServingStatus
+	sent := false
+	for {
+		var current grpc_health_v1.HealthCheckResponse_ServingStatus
+		if check, ok := g.lookup(req.GetService()); ok {
+			current = statusFor(check(ctx))
+		} else {
+			current = grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+
+		if !sent || current != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+			sent = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusFor maps a check's result to the gRPC health checking protocol's
+// serving status.
+func statusFor(err error) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if err != nil {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+var _ grpc_health_v1.HealthServer = (*GRPCHandler)(nil)
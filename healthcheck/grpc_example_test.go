@@ -0,0 +1,54 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func Example_grpc() {
+	healthchecks := NewHandler()
+	healthchecks.AddReadinessCheck("always-fails", func() error {
+		return fmt.Errorf("example failure")
+	})
+
+	// Expose the same checks over the gRPC Health Checking Protocol so that
+	// Envoy, linkerd, or grpc_health_probe can use them too. Every check
+	// already registered with healthchecks is automatically exposed under
+	// its own service name, alongside the overall status under "".
+	grpcHealth := NewGRPCHandler(healthchecks)
+
+	// var grpcServer *grpc.Server
+	// grpc_health_v1.RegisterHealthServer(grpcServer, grpcHealth)
+
+	resp, err := grpcHealth.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status)
+
+	perCheck, err := grpcHealth.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "always-fails"})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(perCheck.Status)
+
+	// Output:
+	// NOT_SERVING
+	// NOT_SERVING
+}
@@ -0,0 +1,251 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package healthcheck provides a simple mechanism for registering liveness
+// and readiness checks and exposing them over HTTP so that infrastructure
+// like Kubernetes can make scheduling and routing decisions.
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Check is a health/readiness check that returns nil if the service is
+// healthy, or an error describing why it isn't.
+//
+// Deprecated: prefer CheckContext, which is passed the context of the
+// inbound request so that checks can honor cancellation and deadlines.
+type Check func() error
+
+// CheckContext is a health/readiness check that returns nil if the service
+// is healthy, or an error describing why it isn't. Unlike Check, it is
+// passed a context derived from the inbound HTTP request, so that checks
+// performing I/O (DNS lookups, database pings, HTTP requests) can abort
+// promptly if the caller goes away or the server is shutting down.
+type CheckContext func(ctx context.Context) error
+
+// adaptCheck turns a legacy Check into a CheckContext that ignores its
+// context, so both registration styles can be stored and invoked
+// uniformly.
+func adaptCheck(check Check) CheckContext {
+	return func(ctx context.Context) error {
+		return check()
+	}
+}
+
+// Handler is an http.Handler with additional methods that take care of
+// common liveness/readiness check operations.
+type Handler interface {
+	http.Handler
+
+	// AddLivenessCheck adds a check that indicates the service should be
+	// restarted or rescheduled if it fails.
+	AddLivenessCheck(name string, check Check)
+
+	// AddReadinessCheck adds a check that indicates the service should stop
+	// receiving traffic if it fails.
+	AddReadinessCheck(name string, check Check)
+
+	// AddLivenessCheckContext adds a context-aware liveness check. The
+	// context passed to check is derived from the request that triggered
+	// the liveness endpoint.
+	AddLivenessCheckContext(name string, check CheckContext)
+
+	// AddReadinessCheckContext adds a context-aware readiness check. The
+	// context passed to check is derived from the request that triggered
+	// the readiness endpoint.
+	AddReadinessCheckContext(name string, check CheckContext)
+
+	// AddLivenessCheckInfo is like AddLivenessCheckContext, but also
+	// attaches component metadata that's surfaced in the detailed
+	// ("?full=1") health report.
+	AddLivenessCheckInfo(name string, check CheckContext, info CheckInfo)
+
+	// AddReadinessCheckInfo is like AddReadinessCheckContext, but also
+	// attaches component metadata that's surfaced in the detailed
+	// ("?full=1") health report.
+	AddReadinessCheckInfo(name string, check CheckContext, info CheckInfo)
+
+	// SetVersionInfo records the version and releaseID reported at the top
+	// level of the detailed ("?full=1") health report.
+	SetVersionInfo(version, releaseID string)
+
+	// LivenessChecks returns a snapshot of every registered liveness check
+	// by name, so that other protocols (e.g. the gRPC health checking
+	// service) can expose the same checks individually instead of callers
+	// re-registering them.
+	LivenessChecks() map[string]CheckContext
+
+	// ReadinessChecks returns a snapshot of every registered readiness
+	// check by name, so that other protocols (e.g. the gRPC health
+	// checking service) can expose the same checks individually instead
+	// of callers re-registering them.
+	ReadinessChecks() map[string]CheckContext
+
+	// LiveEndpoint is the HTTP handler for the liveness endpoint, which by
+	// default is mounted at /live.
+	LiveEndpoint(w http.ResponseWriter, r *http.Request)
+
+	// ReadyEndpoint is the HTTP handler for the readiness endpoint, which by
+	// default is mounted at /ready.
+	ReadyEndpoint(w http.ResponseWriter, r *http.Request)
+}
+
+// NewHandler creates a new basic Handler.
+func NewHandler() Handler {
+	h := &basicHandler{
+		livenessChecks:  map[string]CheckContext{},
+		readinessChecks: map[string]CheckContext{},
+		livenessInfo:    map[string]CheckInfo{},
+		readinessInfo:   map[string]CheckInfo{},
+	}
+	h.ServeMux = http.NewServeMux()
+	h.Handle("/live", http.HandlerFunc(h.LiveEndpoint))
+	h.Handle("/ready", http.HandlerFunc(h.ReadyEndpoint))
+	return h
+}
+
+// basicHandler is a basic Handler implementation.
+type basicHandler struct {
+	*http.ServeMux
+	checksMutex     sync.Mutex
+	livenessChecks  map[string]CheckContext
+	readinessChecks map[string]CheckContext
+	livenessInfo    map[string]CheckInfo
+	readinessInfo   map[string]CheckInfo
+	version         string
+	releaseID       string
+}
+
+func (s *basicHandler) AddLivenessCheck(name string, check Check) {
+	s.AddLivenessCheckContext(name, adaptCheck(check))
+}
+
+func (s *basicHandler) AddReadinessCheck(name string, check Check) {
+	s.AddReadinessCheckContext(name, adaptCheck(check))
+}
+
+func (s *basicHandler) AddLivenessCheckContext(name string, check CheckContext) {
+	s.AddLivenessCheckInfo(name, check, CheckInfo{})
+}
+
+func (s *basicHandler) AddReadinessCheckContext(name string, check CheckContext) {
+	s.AddReadinessCheckInfo(name, check, CheckInfo{})
+}
+
+func (s *basicHandler) AddLivenessCheckInfo(name string, check CheckContext, info CheckInfo) {
+	s.checksMutex.Lock()
+	defer s.checksMutex.Unlock()
+	s.livenessChecks[name] = check
+	s.livenessInfo[name] = info
+}
+
+func (s *basicHandler) AddReadinessCheckInfo(name string, check CheckContext, info CheckInfo) {
+	s.checksMutex.Lock()
+	defer s.checksMutex.Unlock()
+	s.readinessChecks[name] = check
+	s.readinessInfo[name] = info
+}
+
+func (s *basicHandler) SetVersionInfo(version, releaseID string) {
+	s.checksMutex.Lock()
+	defer s.checksMutex.Unlock()
+	s.version = version
+	s.releaseID = releaseID
+}
+
+func (s *basicHandler) LivenessChecks() map[string]CheckContext {
+	s.checksMutex.Lock()
+	defer s.checksMutex.Unlock()
+	out := make(map[string]CheckContext, len(s.livenessChecks))
+	for name, check := range s.livenessChecks {
+		out[name] = check
+	}
+	return out
+}
+
+func (s *basicHandler) ReadinessChecks() map[string]CheckContext {
+	s.checksMutex.Lock()
+	defer s.checksMutex.Unlock()
+	out := make(map[string]CheckContext, len(s.readinessChecks))
+	for name, check := range s.readinessChecks {
+		out[name] = check
+	}
+	return out
+}
+
+func (s *basicHandler) LiveEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, checkSet{s.livenessChecks, s.livenessInfo})
+}
+
+func (s *basicHandler) ReadyEndpoint(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r,
+		checkSet{s.livenessChecks, s.livenessInfo},
+		checkSet{s.readinessChecks, s.readinessInfo})
+}
+
+// checkSet pairs a group of checks with the component metadata registered
+// alongside them.
+type checkSet struct {
+	checks map[string]CheckContext
+	info   map[string]CheckInfo
+}
+
+func (s *basicHandler) handle(w http.ResponseWriter, r *http.Request, sets ...checkSet) {
+	s.checksMutex.Lock()
+	defer s.checksMutex.Unlock()
+
+	report := newReport(s.version, s.releaseID)
+	ctx := r.Context()
+
+	for _, set := range sets {
+		for name, check := range set.checks {
+			report.record(name, set.info[name], check(ctx))
+		}
+	}
+
+	httpStatus := http.StatusOK
+	if report.Status == StatusFail {
+		httpStatus = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(httpStatus)
+	if wantsFullReport(r) {
+		encoded, _ := json.MarshalIndent(report, "", "    ")
+		w.Write(encoded)
+		return
+	}
+	encoded, _ := json.MarshalIndent(report.compact(), "", "    ")
+	w.Write(encoded)
+}
+
+// wantsFullReport decides, per the IETF health-check-response draft's
+// content negotiation, whether the caller wants the detailed report format
+// instead of the default compact one.
+func wantsFullReport(r *http.Request) bool {
+	if r.URL.Query().Get("full") == "1" {
+		return true
+	}
+	for _, accept := range r.Header["Accept"] {
+		if strings.Contains(accept, "application/health+json") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// MetricsSink receives the outcome of every check a metricsHandler runs, so
+// that it can be recorded with whatever metrics backend the caller uses
+// (Prometheus, OpenTelemetry, OpenCensus, ...).
+type MetricsSink interface {
+	// ObserveCheck is called after every run of a check named name (either
+	// "live" or "ready" kind), reporting whether it passed and how long it
+	// took. Slow-but-passing checks are as worth alerting on as failing
+	// ones, so duration is reported regardless of healthy.
+	ObserveCheck(name, kind string, healthy bool, duration time.Duration)
+}
+
+// MetricsHandler is a Handler that also reports the outcome of every check
+// to a MetricsSink.
+type MetricsHandler interface {
+	Handler
+}
+
+// metricsHandler wraps a Handler, reporting the result of every check to a
+// MetricsSink.
+type metricsHandler struct {
+	Handler
+	sink MetricsSink
+}
+
+// NewMetricsHandler returns a MetricsHandler that wraps the given Handler,
+// reporting every check's outcome to sink.
+func NewMetricsHandler(handler Handler, sink MetricsSink) MetricsHandler {
+	return &metricsHandler{
+		Handler: handler,
+		sink:    sink,
+	}
+}
+
+func (h *metricsHandler) AddLivenessCheck(name string, check Check) {
+	h.AddLivenessCheckContext(name, adaptCheck(check))
+}
+
+func (h *metricsHandler) AddReadinessCheck(name string, check Check) {
+	h.AddReadinessCheckContext(name, adaptCheck(check))
+}
+
+func (h *metricsHandler) AddLivenessCheckContext(name string, check CheckContext) {
+	h.AddLivenessCheckInfo(name, check, CheckInfo{})
+}
+
+func (h *metricsHandler) AddReadinessCheckContext(name string, check CheckContext) {
+	h.AddReadinessCheckInfo(name, check, CheckInfo{})
+}
+
+func (h *metricsHandler) AddLivenessCheckInfo(name string, check CheckContext, info CheckInfo) {
+	h.Handler.AddLivenessCheckInfo(name, h.wrap("live", name, check), info)
+}
+
+func (h *metricsHandler) AddReadinessCheckInfo(name string, check CheckContext, info CheckInfo) {
+	h.Handler.AddReadinessCheckInfo(name, h.wrap("ready", name, check), info)
+}
+
+func (h *metricsHandler) wrap(kind, name string, check CheckContext) CheckContext {
+	observe := func(ctx context.Context) error {
+		start := time.Now()
+		err := check(ctx)
+		h.sink.ObserveCheck(name, kind, err == nil, time.Since(start))
+		return err
+	}
+	// Run the check once up front so it's reported even before the first
+	// request to the health endpoint.
+	observe(context.Background())
+	return observe
+}
+
+var _ http.Handler = (*metricsHandler)(nil)
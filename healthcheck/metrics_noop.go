@@ -0,0 +1,25 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import "time"
+
+// NoopSink is a MetricsSink that discards every observation. It's useful
+// as a default when metrics are optional, avoiding a nil check at every
+// call site.
+type NoopSink struct{}
+
+// ObserveCheck implements MetricsSink.
+func (NoopSink) ObserveCheck(name, kind string, healthy bool, duration time.Duration) {}
@@ -0,0 +1,97 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// checkKey identifies a check by its kind ("live" or "ready") and name.
+type checkKey struct {
+	kind, name string
+}
+
+// OTelSink is a MetricsSink that reports current check status as an
+// observable gauge and check latency as a histogram, both recorded
+// through an OpenTelemetry meter.
+type OTelSink struct {
+	checkDuration metric.Float64Histogram
+
+	mu      sync.Mutex
+	healthy map[checkKey]bool
+}
+
+// NewOTelSink returns an OTelSink that records its instruments through
+// meter.
+func NewOTelSink(meter metric.Meter) (*OTelSink, error) {
+	s := &OTelSink{healthy: map[checkKey]bool{}}
+
+	checkDuration, err := meter.Float64Histogram(
+		"healthcheck.duration",
+		metric.WithDescription("Time it took to run a check, regardless of whether it passed"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.checkDuration = checkDuration
+
+	// An observable gauge reports the current state of every known check
+	// whenever it's collected, rather than accumulating readings the way
+	// a counter would.
+	_, err = meter.Int64ObservableGauge(
+		"healthcheck",
+		metric.WithDescription("Indicates if check is healthy (1 is healthy, 0 is unhealthy)"),
+		metric.WithInt64Callback(s.report),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *OTelSink) report(_ context.Context, o metric.Int64Observer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, healthy := range s.healthy {
+		value := int64(0)
+		if healthy {
+			value = 1
+		}
+		o.Observe(value, metric.WithAttributes(
+			attribute.String("check", key.kind),
+			attribute.String("name", key.name),
+		))
+	}
+	return nil
+}
+
+// ObserveCheck implements MetricsSink.
+func (s *OTelSink) ObserveCheck(name, kind string, healthy bool, duration time.Duration) {
+	s.mu.Lock()
+	s.healthy[checkKey{kind: kind, name: name}] = healthy
+	s.mu.Unlock()
+
+	attrs := metric.WithAttributes(
+		attribute.String("check", kind),
+		attribute.String("name", name),
+	)
+	s.checkDuration.Record(context.Background(), duration.Seconds(), attrs)
+}
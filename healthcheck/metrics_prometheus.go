@@ -0,0 +1,56 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusSink is a MetricsSink that reports check status as a gauge and
+// check latency as a histogram, both registered with a Prometheus
+// registry.
+type PrometheusSink struct {
+	checkStatus   *prometheus.GaugeVec
+	checkDuration *prometheus.HistogramVec
+}
+
+// NewPrometheusSink returns a PrometheusSink that registers its metrics
+// with registry.
+func NewPrometheusSink(registry prometheus.Registerer) *PrometheusSink {
+	s := &PrometheusSink{
+		checkStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "healthcheck",
+			Help: "Indicates if check is healthy (1 is healthy, 0 is unhealthy)",
+		}, []string{"check", "name"}),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "healthcheck_duration_seconds",
+			Help: "Time it took to run a check, regardless of whether it passed",
+		}, []string{"check", "name"}),
+	}
+	registry.MustRegister(s.checkStatus, s.checkDuration)
+	return s
+}
+
+// ObserveCheck implements MetricsSink.
+func (s *PrometheusSink) ObserveCheck(name, kind string, healthy bool, duration time.Duration) {
+	value := 0.0
+	if healthy {
+		value = 1.0
+	}
+	s.checkStatus.WithLabelValues(kind, name).Set(value)
+	s.checkDuration.WithLabelValues(kind, name).Observe(duration.Seconds())
+}
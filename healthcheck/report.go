@@ -0,0 +1,188 @@
+// Copyright 2020 by the contributors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthcheck
+
+import (
+	"errors"
+	"time"
+)
+
+// Status is the outcome of a single check, or the aggregate outcome of a
+// whole report, as defined by the IETF "health-check-response-format-for-http-apis"
+// draft.
+type Status string
+
+const (
+	// StatusPass indicates the component is healthy.
+	StatusPass Status = "pass"
+	// StatusWarn indicates the component is healthy enough to keep serving,
+	// but something about it deserves attention.
+	StatusWarn Status = "warn"
+	// StatusFail indicates the component is unhealthy.
+	StatusFail Status = "fail"
+)
+
+// ComponentType classifies what kind of dependency a check is exercising,
+// for the detailed ("?full=1") health report.
+type ComponentType string
+
+const (
+	// ComponentDatastore is a check against a database, cache, queue, or
+	// other stateful store.
+	ComponentDatastore ComponentType = "datastore"
+	// ComponentSystem is a check against the local system, e.g. goroutine
+	// or file descriptor counts, disk space, or GC pauses.
+	ComponentSystem ComponentType = "system"
+	// ComponentComponent is a check against any other internal or
+	// upstream component. This is the default when no CheckInfo is given.
+	ComponentComponent ComponentType = "component"
+)
+
+// CheckInfo is metadata about a check, surfaced in the detailed
+// ("?full=1") health report alongside its pass/fail outcome.
+type CheckInfo struct {
+	// ComponentType classifies the dependency being checked. Defaults to
+	// ComponentComponent if empty.
+	ComponentType ComponentType
+	// ObservedUnit is the unit of the value a check observes, e.g. "ms" or
+	// "goroutines". It's only shown if the check's error carries an
+	// ObservedValue (see CheckError).
+	ObservedUnit string
+}
+
+// CheckError lets a Check or CheckContext attach an observed value (e.g. a
+// goroutine count or GC pause duration) and/or downgrade a failure to a
+// warning, while still satisfying the standard error interface.
+type CheckError struct {
+	// Err is the underlying failure. A nil Err with Warn set to true
+	// reports a passing check that nonetheless wants to surface an
+	// observed value or a warning message.
+	Err error
+	// ObservedValue is reported in the detailed health report's
+	// observedValue field for this check.
+	ObservedValue interface{}
+	// Warn reports this check as "warn" instead of "fail" when Err is
+	// non-nil.
+	Warn bool
+}
+
+func (e *CheckError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+	return e.Err.Error()
+}
+
+// Unwrap allows CheckError to participate in errors.Is / errors.As.
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
+// CheckDetail is a single check's entry in a Report's detailed output.
+type CheckDetail struct {
+	Status        Status        `json:"status"`
+	ComponentType ComponentType `json:"componentType,omitempty"`
+	Time          time.Time     `json:"time"`
+	ObservedValue interface{}   `json:"observedValue,omitempty"`
+	ObservedUnit  string        `json:"observedUnit,omitempty"`
+	Output        string        `json:"output,omitempty"`
+}
+
+// Report is the detailed ("?full=1" or "Accept: application/health+json")
+// health report for a Handler, following the shape of the IETF
+// health-check-response draft.
+type Report struct {
+	Status    Status                   `json:"status"`
+	Version   string                   `json:"version,omitempty"`
+	ReleaseID string                   `json:"releaseID,omitempty"`
+	Checks    map[string][]CheckDetail `json:"checks,omitempty"`
+}
+
+func newReport(version, releaseID string) *Report {
+	return &Report{
+		Status:    StatusPass,
+		Version:   version,
+		ReleaseID: releaseID,
+		Checks:    map[string][]CheckDetail{},
+	}
+}
+
+// record adds a check's outcome to the report and folds it into the
+// top-level aggregate status.
+func (r *Report) record(name string, info CheckInfo, err error) {
+	componentType := info.ComponentType
+	if componentType == "" {
+		componentType = ComponentComponent
+	}
+
+	detail := CheckDetail{
+		Status:        StatusPass,
+		ComponentType: componentType,
+		Time:          time.Now(),
+	}
+
+	var checkErr *CheckError
+	if errors.As(err, &checkErr) {
+		detail.ObservedValue = checkErr.ObservedValue
+		if checkErr.ObservedValue != nil {
+			detail.ObservedUnit = info.ObservedUnit
+		}
+		err = checkErr.Err
+		switch {
+		case err != nil && checkErr.Warn:
+			detail.Status = StatusWarn
+		case err != nil:
+			detail.Status = StatusFail
+		case checkErr.Warn:
+			detail.Status = StatusWarn
+		}
+	} else if err != nil {
+		detail.Status = StatusFail
+	}
+	if err != nil {
+		detail.Output = err.Error()
+	}
+
+	r.Checks[name] = append(r.Checks[name], detail)
+	r.aggregate(detail.Status)
+}
+
+// aggregate folds a single check's status into the report's overall
+// status: any fail wins, otherwise any warn wins, otherwise pass.
+func (r *Report) aggregate(status Status) {
+	switch {
+	case r.Status == StatusFail || status == StatusFail:
+		r.Status = StatusFail
+	case r.Status == StatusWarn || status == StatusWarn:
+		r.Status = StatusWarn
+	default:
+		r.Status = StatusPass
+	}
+}
+
+// compact renders the report in the library's original terse format: a
+// flat map of check name to "OK" or the check's error message.
+func (r *Report) compact() map[string]string {
+	out := make(map[string]string, len(r.Checks))
+	for name, details := range r.Checks {
+		detail := details[len(details)-1]
+		if detail.Status == StatusFail || detail.Status == StatusWarn {
+			out[name] = detail.Output
+		} else {
+			out[name] = "OK"
+		}
+	}
+	return out
+}